@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/AdityaVallabh/swagger_meqa/meqa/mqplan"
+	"github.com/AdityaVallabh/swagger_meqa/meqa/mqswag"
+	"github.com/AdityaVallabh/swagger_meqa/meqa/mqutil"
+)
+
+// runMeqa loads the swagger file and test plan, runs the requested suite(s),
+// and writes out results/failures. It takes its arguments as pointers so
+// main and the tests can share it without main having to re-parse flags.
+func runMeqa(meqaPath, swaggerPath, planPath, resultPath, testToRun, username, password, apitoken, baseURL, dataset, fuzzType *string, batchSize *int, repro, verbose *bool, coverageCorpusPath *string, minimizeFailures *bool, workers *int, dedup *bool, reportWebhook *string, statsPath *string) {
+	mqutil.Verbose = *verbose
+
+	db, err := mqswag.CreateDBFromSwaggerFile(*swaggerPath, *meqaPath)
+	if err != nil {
+		mqutil.Logger.Println(err.Error())
+		os.Exit(1)
+	}
+	db.Username = *username
+	db.Password = *password
+	db.ApiToken = *apitoken
+	if *baseURL != "" {
+		db.Swagger.BasePath = *baseURL
+	}
+
+	plan := &mqplan.Current
+	plan.FuzzType = *fuzzType
+	plan.Repro = *repro
+	plan.CoverageCorpusPath = *coverageCorpusPath
+	plan.MinimizeFailures = *minimizeFailures
+	plan.Workers = *workers
+	plan.Dedup = *dedup
+	plan.StatsPath = *statsPath
+
+	// FileReporter writes MeqaFails/NewFails once the run finishes; RunAll
+	// calls its OnFinish exactly once regardless of how many suites ran.
+	plan.Reporters = append(plan.Reporters, mqplan.FileReporter{Plan: plan, Path: *meqaPath})
+	if *reportWebhook != "" {
+		plan.Reporters = append(plan.Reporters, mqplan.NewWebhookReporter(*reportWebhook))
+	}
+
+	if err := plan.InitFromFile(*planPath, db); err != nil {
+		mqutil.Logger.Println(err.Error())
+		os.Exit(1)
+	}
+	if err := plan.ReadFails(*meqaPath); err != nil {
+		mqutil.Logger.Println(err.Error())
+	}
+
+	var names []string
+	if *testToRun == "all" {
+		for _, tc := range plan.SuiteList {
+			names = append(names, tc.Name)
+		}
+	} else {
+		names = []string{*testToRun}
+	}
+	resultCounts, err := plan.RunAll(names)
+	if err != nil {
+		mqutil.Logger.Println(err.Error())
+	}
+	plan.ResultCounts = resultCounts
+
+	plan.LogErrors()
+	plan.PrintSummary()
+	if err := plan.WriteResultToFile(*resultPath); err != nil {
+		mqutil.Logger.Println(err.Error())
+	}
+	if plan.CoverageCorpus != nil {
+		if err := plan.CoverageCorpus.Save(plan.CoverageCorpusPath); err != nil {
+			mqutil.Logger.Println(err.Error())
+		}
+	}
+}
+
+func main() {
+	meqaPath := flag.String("meqa", ".", "The directory where meqa config and data files live")
+	swaggerPath := flag.String("swagger", "", "The swagger json/yaml file to test against")
+	planPath := flag.String("plan", "", "The test plan yaml file")
+	resultPath := flag.String("result", "result.yml", "Where to write the test results")
+	testToRun := flag.String("test", "all", "Which named test suite to run, or \"all\"")
+	username := flag.String("username", "", "Username for basic auth")
+	password := flag.String("password", "", "Password for basic auth")
+	apitoken := flag.String("apitoken", "", "API token, sent as a bearer token")
+	baseURL := flag.String("baseurl", "", "Override the swagger file's base URL")
+	dataset := flag.String("dataset", "", "Dataset file to seed object creation from")
+	fuzzType := flag.String("fuzz", mqutil.FuzzAll, "Which fuzz type to run")
+	batchSize := flag.Int("batch", 0, "How many objects to create per run")
+	repro := flag.Bool("repro", false, "Only reproduce previously recorded failures")
+	verbose := flag.Bool("verbose", false, "Verbose logging")
+	coverageCorpusPath := flag.String("coverage-corpus", "", "Path to a coverage-guided fuzzing corpus to load and update")
+	minimizeFailures := flag.Bool("minimize", false, "Shrink each new failure to a minimal reproducer before writing it out")
+	workers := flag.Int("workers", 1, "How many test suites to run concurrently")
+	dedup := flag.Bool("dedup", false, "Write one representative failure per unique fingerprint instead of one per occurrence")
+	reportWebhook := flag.String("report-webhook", "", "POST each failure to this URL as JSON as it's found")
+	statsPath := flag.String("stats", "", "Append a periodic throughput snapshot to this file, for soak/nightly fuzz runs")
+
+	flag.Parse()
+
+	mqutil.Logger = mqutil.NewFileLogger(filepath.Join(*meqaPath, "mqgo.log"))
+	runMeqa(meqaPath, swaggerPath, planPath, resultPath, testToRun, username, password, apitoken, baseURL, dataset, fuzzType, batchSize, repro, verbose, coverageCorpusPath, minimizeFailures, workers, dedup, reportWebhook, statsPath)
+}
@@ -21,8 +21,15 @@ func TestMqgo(t *testing.T) {
 	batchSize := 0
 	repro, verbose := false, false
 
+	coverageCorpusPath := ""
+	minimizeFailures := false
+	workers := 1
+	dedup := false
+	reportWebhook := ""
+	statsPath := ""
+
 	mqutil.Logger = mqutil.NewFileLogger(filepath.Join(meqaPath, "mqgo.log"))
-	runMeqa(&meqaPath, &swaggerPath, &planPath, &resultPath, &testToRun, &username, &password, &apitoken, &baseURL, &dataset, &fuzzType, &batchSize, &repro, &verbose)
+	runMeqa(&meqaPath, &swaggerPath, &planPath, &resultPath, &testToRun, &username, &password, &apitoken, &baseURL, &dataset, &fuzzType, &batchSize, &repro, &verbose, &coverageCorpusPath, &minimizeFailures, &workers, &dedup, &reportWebhook, &statsPath)
 }
 
 func TestMain(m *testing.M) {
@@ -0,0 +1,256 @@
+package mqplan
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/AdityaVallabh/swagger_meqa/meqa/mqswag"
+	"github.com/AdityaVallabh/swagger_meqa/meqa/mqutil"
+)
+
+// corpusExploreRate is the fraction of the time Run lets a test fuzz
+// completely at random instead of biasing toward the corpus, so the
+// scheduler keeps exploring instead of converging on the same few
+// signatures.
+const corpusExploreRate = 0.2
+
+// CorpusCandidate is a single field/value combination recorded because it
+// produced an interesting (rare or previously-unseen) response signature.
+type CorpusCandidate struct {
+	Endpoint string           `json:"endpoint"`
+	Method   string           `json:"method"`
+	Field    string           `json:"field"`
+	Value    mqutil.FuzzValue `json:"value"`
+	Hits     int              `json:"hits"`
+}
+
+// CorpusDB is a persistent, signature-keyed corpus of fuzz inputs. It plays
+// the role syzkaller's corpus plays for coverage-guided kernel fuzzing,
+// except the "coverage" here is a response signature (endpoint + method +
+// status class + top-level body keys) rather than code coverage. Run uses
+// it to bias dup.Run(tc) toward field/value combinations whose parent
+// signatures were rare or newly discovered in previous runs.
+type CorpusDB struct {
+	mutex   sync.Mutex
+	path    string
+	entries map[string][]*CorpusCandidate // signature -> candidates that produced it
+	new     int                           // signatures discovered since Load
+}
+
+// LoadCorpusDB reads a CorpusDB previously written by Save, in the JSONL
+// format used elsewhere in mqplan (one CorpusCandidate per line, keyed by
+// signature). A missing file is not an error; it just means an empty
+// corpus, same as a first run.
+func LoadCorpusDB(path string) (*CorpusDB, error) {
+	c := &CorpusDB{path: path, entries: make(map[string][]*CorpusCandidate)}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	d := json.NewDecoder(f)
+	for {
+		var line struct {
+			Signature string          `json:"signature"`
+			Candidate CorpusCandidate `json:"candidate"`
+		}
+		if err := d.Decode(&line); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		c.entries[line.Signature] = append(c.entries[line.Signature], &line.Candidate)
+	}
+	return c, nil
+}
+
+// ResponseSignature computes a coverage signature for a test response: the
+// endpoint, method, HTTP status class (2xx, 4xx, ...), and the sorted set
+// of top-level keys in the response body. Two responses that hit the same
+// endpoint/method, fail the same way, and shape their body the same way
+// are considered the same signature even if the fuzzed value differs.
+func ResponseSignature(endpoint, method string, statusCode int, body map[string]interface{}) string {
+	keys := make([]string, 0, len(body))
+	for k := range body {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	raw := fmt.Sprintf("%s %s %dxx [%s]", method, endpoint, statusCode/100, strings.Join(keys, ","))
+	sum := sha1.Sum([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// Record adds a candidate under signature and reports whether it is worth
+// keeping around for future bias: true when the signature had not been
+// seen before (by this CorpusDB instance) or the candidate's
+// endpoint/method/field/value is new under it. A candidate already on
+// record has its Hits bumped and counts as interesting only while its
+// signature has fewer than 5 distinct candidates.
+func (c *CorpusDB) Record(signature string, candidate *CorpusCandidate) (interesting bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	existing := c.entries[signature]
+	if existing == nil {
+		c.new++
+	}
+	for _, e := range existing {
+		if e.Endpoint == candidate.Endpoint && e.Method == candidate.Method && e.Field == candidate.Field && reflect.DeepEqual(e.Value, candidate.Value) {
+			e.Hits++
+			return len(existing) < 5
+		}
+	}
+	c.entries[signature] = append(existing, candidate)
+	return true
+}
+
+// Pick returns a previously-recorded candidate for endpoint/method/field,
+// favoring the candidate with the fewest Hits across all signatures, or nil if the
+// corpus has nothing for this endpoint/method/field yet. A caller should
+// still fall back to ordinary random fuzzing occasionally (see
+// corpusExploreRate) so the corpus keeps growing instead of flattening out.
+func (c *CorpusDB) Pick(endpoint, method, field string) *CorpusCandidate {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var best *CorpusCandidate
+	for _, candidates := range c.entries {
+		for _, cand := range candidates {
+			if cand.Endpoint != endpoint || cand.Method != method || cand.Field != field {
+				continue
+			}
+			if best == nil || cand.Hits < best.Hits {
+				best = cand
+			}
+		}
+	}
+	return best
+}
+
+// NewSignatures reports how many distinct signatures this CorpusDB has
+// discovered since it was loaded.
+func (c *CorpusDB) NewSignatures() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.new
+}
+
+// Size reports the total number of distinct signatures in the corpus.
+func (c *CorpusDB) Size() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.entries)
+}
+
+// Save writes the corpus back out as JSONL, one candidate per signature
+// per line, mirroring the shape WriteFailures uses for MeqaFails.
+func (c *CorpusDB) Save(path string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for signature, candidates := range c.entries {
+		for _, cand := range candidates {
+			line := struct {
+				Signature string           `json:"signature"`
+				Candidate *CorpusCandidate `json:"candidate"`
+			}{signature, cand}
+			if err := enc.Encode(line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// recordCoverage computes the response signature for dup's last run and
+// records every query, form and header parameter dup sent in
+// plan.CoverageCorpus - not just the
+// ones that came back in failed, which only lists fields that produced a
+// schema/response failure. The overwhelming majority of fuzzed requests
+// succeed, and a corpus that only ever grew from failures would degenerate
+// into a table of known-bad values to replay rather than the
+// coverage-guided exploration Pick/biasTestParams are meant to drive.
+// failed is used only to recover a fuzzed field's original FuzzType; dup's
+// own params hold the value that was actually sent, for passing fields too.
+// Path and body parameters are not recorded.
+func (plan *TestPlan) recordCoverage(dup *Test, failed []*mqswag.Payload) {
+	var body map[string]interface{}
+	json.Unmarshal(dup.resp.Body(), &body)
+	signature := ResponseSignature(dup.Path, dup.Method, dup.resp.StatusCode(), body)
+
+	fuzzTypes := make(map[string]string, len(failed))
+	for _, p := range failed {
+		fuzzTypes[p.Field] = p.FuzzType
+	}
+
+	isNew := false
+	record := func(field string, value interface{}) {
+		candidate := &CorpusCandidate{
+			Endpoint: dup.Path,
+			Method:   dup.Method,
+			Field:    field,
+			Value:    mqutil.FuzzValue{Value: value, FuzzType: fuzzTypes[field]},
+		}
+		if plan.CoverageCorpus.Record(signature, candidate) {
+			isNew = true
+		}
+	}
+	for field, value := range dup.QueryParams {
+		record(field, value)
+	}
+	for field, value := range dup.FormParams {
+		record(field, value)
+	}
+	for field, value := range dup.HeaderParams {
+		record(field, value)
+	}
+	if isNew {
+		plan.mutex.Lock()
+		plan.newSignaturesThisRun++
+		plan.mutex.Unlock()
+	}
+}
+
+// biasTestParams mutates dup's TestParams in place to prefer a corpus
+// candidate for a field that already has one on record, some fraction of
+// the time, and otherwise leaves dup untouched so ordinary random fuzzing
+// in dup.Run(tc) still runs for exploration.
+func biasTestParams(corpus *CorpusDB, dup *Test) {
+	if corpus == nil || rand.Float64() < corpusExploreRate {
+		return
+	}
+	for field := range dup.QueryParams {
+		if cand := corpus.Pick(dup.Path, dup.Method, field); cand != nil {
+			dup.QueryParams[field] = cand.Value.Value
+		}
+	}
+	for field := range dup.FormParams {
+		if cand := corpus.Pick(dup.Path, dup.Method, field); cand != nil {
+			dup.FormParams[field] = cand.Value.Value
+		}
+	}
+	for field := range dup.HeaderParams {
+		if cand := corpus.Pick(dup.Path, dup.Method, field); cand != nil {
+			dup.HeaderParams[field] = cand.Value.Value
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package mqplan
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/AdityaVallabh/swagger_meqa/meqa/mqswag"
+)
+
+// errorNoiseRe strips the parts of a server error message that vary from
+// request to request without indicating a different failure: ids, UUIDs
+// and timestamps.
+var errorNoiseRe = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F-]{27}|\d+`)
+
+// Fingerprint reduces a failure to its endpoint, method, field, response
+// status class, and a normalized error signature, so that failures which
+// only differ in the specific fuzzed value or in non-deterministic parts
+// of the server's error message bucket together instead of each looking
+// like a brand new failure.
+func Fingerprint(payload *mqswag.Payload, statusCode int, errMsg string) string {
+	raw := fmt.Sprintf("%s %s %s %dxx %s", payload.Endpoint, payload.Method, payload.Field, statusCode/100, normalizeErrorMessage(errMsg))
+	sum := sha1.Sum([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeErrorMessage keeps the first line of a server error message and
+// replaces digits and UUID-like tokens with "#", so messages that only
+// differ by an id or a timestamp normalize to the same signature.
+func normalizeErrorMessage(msg string) string {
+	if idx := strings.IndexByte(msg, '\n'); idx >= 0 {
+		msg = msg[:idx]
+	}
+	return errorNoiseRe.ReplaceAllString(msg, "#")
+}
+
+// loadFailureRecords reads an existing MeqaFails file written by
+// WriteFailures and returns its FailureRecords keyed by Fingerprint, plus
+// the fingerprints in file order, so writeFailuresDeduped can fold this
+// run's counts into whatever's already on disk instead of starting over. A
+// missing file just means there's nothing to fold in yet.
+//
+// Records written before Dedup existed have no Fingerprint field, so one is
+// computed for them from status 0 and an empty error message - the same
+// degraded inputs normalizeErrorMessage sees for a failure with no response
+// at all - which is enough to still fold identical legacy entries into one
+// another.
+func loadFailureRecords(path string) (map[string]*FailureRecord, []string) {
+	records := make(map[string]*FailureRecord)
+	var order []string
+
+	f, err := os.Open(path)
+	if err != nil {
+		return records, order
+	}
+	defer f.Close()
+
+	d := json.NewDecoder(f)
+	for {
+		var record FailureRecord
+		if err := d.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			break
+		}
+		if record.Payload == nil {
+			continue
+		}
+		if record.Fingerprint == "" {
+			record.Fingerprint = Fingerprint(record.Payload, 0, "")
+		}
+		if _, ok := records[record.Fingerprint]; !ok {
+			order = append(order, record.Fingerprint)
+		}
+		records[record.Fingerprint] = &record
+	}
+	return records, order
+}
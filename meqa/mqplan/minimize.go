@@ -0,0 +1,180 @@
+package mqplan
+
+import "github.com/AdityaVallabh/swagger_meqa/meqa/mqswag"
+
+// FailureRecord is what actually gets written to MeqaFails/NewFails: the
+// original failure payload, plus whatever extra bookkeeping mqplan has
+// accumulated about it (a minimized reproducer, a fingerprint, ...). It
+// embeds *mqswag.Payload so the JSON it produces stays a superset of the
+// plain Payload shape ReadFails already knows how to decode.
+type FailureRecord struct {
+	*mqswag.Payload
+	MinimalRepro *TestParams `json:"minimalRepro,omitempty"`
+	Fingerprint  string      `json:"fingerprint,omitempty"`
+	Count        int         `json:"count,omitempty"`
+}
+
+// failureOrigin remembers which Test produced a failure payload, and in
+// which suite, so minimizeSuite can replay it later.
+type failureOrigin struct {
+	dup *Test
+	tc  *TestSuite
+}
+
+// minimizeSuite shrinks every failure that originated from tc to a smaller
+// reproducer using a ddmin-style bisection over the offending test's
+// parameters: drop optional fields one at a time, shrink the remaining
+// string/numeric values toward empty/zero, and keep a change only if
+// replaying the test still produces a failure on the same field. The
+// result is stored in plan.minimalRepros and picked up by WriteFailures.
+//
+// Run calls this for tc right before it returns, while tc.db (cloned at
+// the top of Run) is still set - minimizeTest replays tests against tc,
+// and Run's own defer clears tc.db the moment its caller gets control
+// back. Restricting this to tc's own failures, rather than all of
+// plan.NewFailures, is what makes that safe: a payload that originated
+// from some other suite would replay against a tc.db that's already gone.
+func (plan *TestPlan) minimizeSuite(tc *TestSuite) {
+	plan.mutex.Lock()
+	var payloads []*mqswag.Payload
+	origins := make(map[*mqswag.Payload]*failureOrigin)
+	fingerprints := make(map[*mqswag.Payload]string)
+	for _, p := range plan.NewFailures {
+		origin := plan.failureOrigins[p]
+		if origin == nil || origin.tc != tc {
+			continue
+		}
+		payloads = append(payloads, p)
+		origins[p] = origin
+		fingerprints[p] = plan.fingerprints[p]
+	}
+	plan.mutex.Unlock()
+
+	for _, payload := range payloads {
+		origin := origins[payload]
+		// Give this payload its own duplicate to minimize against. One
+		// response commonly flags several fields at once, so several
+		// payloads share the same origin.dup - and minimizeTest mutates a
+		// Test's params in place, so minimizing them against the shared dup
+		// would let the second field's bisection build on whatever the
+		// first field's bisection already shrank away, unverified for the
+		// second field's own failure.
+		fresh := origin.dup.SchemaDuplicate()
+		minimal := minimizeTest(fresh, origin.tc, payload.Field, fingerprints[payload])
+		if minimal == nil {
+			continue
+		}
+		plan.mutex.Lock()
+		if plan.minimalRepros == nil {
+			plan.minimalRepros = make(map[*mqswag.Payload]*TestParams)
+		}
+		plan.minimalRepros[payload] = minimal
+		plan.mutex.Unlock()
+	}
+}
+
+// stillFails re-runs dup against tc and reports whether it still produces
+// the same failure - same field and the same Fingerprint (endpoint, method,
+// field, status class and normalized error) - as wantFingerprint. Checking
+// the fingerprint, not just the field name, matters here: a minimization
+// step that turns one kind of failure into a different one on the same
+// field (say a 500 into an unrelated 400) must not be accepted as "still
+// failing", or the resulting MinimalRepro would end up reproducing the
+// wrong bug.
+func stillFails(dup *Test, tc *TestSuite, field, wantFingerprint string) bool {
+	payloads, _ := dup.Run(tc)
+	errMsg := ""
+	if dup.responseError != nil {
+		errMsg = dup.responseError.Error()
+	}
+	statusCode := 0
+	if dup.resp != nil {
+		statusCode = dup.resp.StatusCode()
+	}
+	for _, p := range payloads {
+		if p.Field == field && Fingerprint(p, statusCode, errMsg) == wantFingerprint {
+			return true
+		}
+	}
+	return false
+}
+
+// shrinkValue returns a smaller candidate for v (a shorter string, or a
+// number halved toward zero) and whether one exists.
+func shrinkValue(v interface{}) (interface{}, bool) {
+	switch val := v.(type) {
+	case string:
+		if len(val) > 1 {
+			return val[:len(val)/2], true
+		}
+	case int:
+		if val != 0 {
+			return val / 2, true
+		}
+	case float64:
+		if val != 0 {
+			return val / 2, true
+		}
+	}
+	return v, false
+}
+
+// minimizeMap bisects away keys of m, and shrinks the values of the keys it
+// can't drop, re-running dup after each candidate change and keeping it
+// only if the field is still failing. Reports whether anything shrank.
+func minimizeMap(m map[string]interface{}, dup *Test, tc *TestSuite, field, wantFingerprint string) bool {
+	shrunk := false
+	for key, val := range m {
+		saved := val
+		delete(m, key)
+		if stillFails(dup, tc, field, wantFingerprint) {
+			shrunk = true
+			continue
+		}
+		m[key] = saved
+
+		for {
+			next, ok := shrinkValue(m[key])
+			if !ok {
+				break
+			}
+			prev := m[key]
+			m[key] = next
+			if stillFails(dup, tc, field, wantFingerprint) {
+				shrunk = true
+				continue
+			}
+			m[key] = prev
+			break
+		}
+	}
+	return shrunk
+}
+
+// minimizeTest bisects away optional parameters from dup's TestParams,
+// returning the minimized TestParams if anything shrank, or nil if dup's
+// request was already minimal. wantFingerprint is the Fingerprint of the
+// failure being minimized; a candidate change is only kept if field still
+// produces that exact failure, not merely some failure on field.
+func minimizeTest(dup *Test, tc *TestSuite, field, wantFingerprint string) *TestParams {
+	shrunk := false
+	if minimizeMap(dup.QueryParams, dup, tc, field, wantFingerprint) {
+		shrunk = true
+	}
+	if minimizeMap(dup.FormParams, dup, tc, field, wantFingerprint) {
+		shrunk = true
+	}
+	if minimizeMap(dup.HeaderParams, dup, tc, field, wantFingerprint) {
+		shrunk = true
+	}
+	if bodyMap, ok := dup.BodyParams.(map[string]interface{}); ok {
+		if minimizeMap(bodyMap, dup, tc, field, wantFingerprint) {
+			shrunk = true
+		}
+	}
+	if !shrunk {
+		return nil
+	}
+	minimal := dup.TestParams
+	return &minimal
+}
@@ -135,6 +135,49 @@ type TestPlan struct {
 	comment  string
 	FuzzType string
 	Repro    bool
+
+	// CoverageCorpusPath, when non-empty, makes Init load a CorpusDB from
+	// this path and Run bias fuzz value selection toward field/value
+	// combinations that previously produced rare or new response
+	// signatures. Saving the updated corpus back to disk is the caller's
+	// responsibility (see CoverageCorpus.Save).
+	CoverageCorpusPath string
+	CoverageCorpus     *CorpusDB
+
+	newSignaturesThisRun int
+
+	// MinimizeFailures, when set, makes Run call minimizeSuite on each
+	// suite's new failures before WriteFailures serializes it, so MeqaFails/NewFails
+	// carry an actionable minimal reproducer alongside the original.
+	MinimizeFailures bool
+	failureOrigins   map[*mqswag.Payload]*failureOrigin
+	minimalRepros    map[*mqswag.Payload]*TestParams
+
+	// Workers controls how many top-level test suites RunAll runs
+	// concurrently. A suite with Ref chains or POST-then-GET dependencies
+	// is always serialized within itself, since Run already clones its
+	// own db for the suite; Workers only parallelizes across suites.
+	// 0 or 1 means sequential, matching the historical behavior.
+	Workers int
+	mutex   sync.Mutex // guards resultList, NewFailures and the failure/coverage bookkeeping fields
+
+	// Dedup, when set, makes WriteFailures write one representative
+	// payload per unique Fingerprint instead of one per failure, with a
+	// Count of how many times that fingerprint occurred.
+	Dedup             bool
+	fingerprints      map[*mqswag.Payload]string
+	fingerprintCounts map[string]int
+
+	// Reporters are notified as each test finishes, rather than only
+	// once at the end of a run.
+	Reporters []Reporter
+
+	// StatsPath, when non-empty, makes Init start a StatsRecorder that
+	// appends a throughput snapshot to this file every StatsInterval (30
+	// seconds if unset), for soak/nightly-fuzz sessions.
+	StatsPath     string
+	StatsInterval time.Duration
+	stats         *StatsRecorder
 }
 
 // Add a new TestSuite, returns whether the Case is successfully added.
@@ -303,6 +346,15 @@ func ReadMetadata(path string) map[string]interface{} {
 
 // WriteFailures writes new failures to mqfails file
 func (plan *TestPlan) WriteFailures(path string) error {
+	if plan.Dedup && !plan.Repro {
+		// The append path below counts duplicates only within this run, so
+		// the same fingerprint re-appearing across runs would just grow
+		// MeqaFails with another Count-1 row instead of bumping the count
+		// on the one that's already there. writeFailuresDeduped folds this
+		// run's counts into what's already on disk instead.
+		return plan.writeFailuresDeduped(path)
+	}
+
 	flags := os.O_CREATE | os.O_WRONLY
 	var perms os.FileMode
 	if plan.Repro {
@@ -337,12 +389,89 @@ func (plan *TestPlan) WriteFailures(path string) error {
 			}
 		}
 	}
+	writtenFingerprints := make(map[string]bool)
 	for _, v := range plan.NewFailures {
 		v.Meta = meta
-		if err := d1.Encode(v); err != nil {
+		fp := plan.fingerprints[v]
+		if plan.Dedup {
+			if writtenFingerprints[fp] {
+				continue
+			}
+			writtenFingerprints[fp] = true
+		}
+		record := FailureRecord{
+			Payload:      v,
+			MinimalRepro: plan.minimalRepros[v],
+			Fingerprint:  fp,
+			Count:        plan.fingerprintCounts[fp],
+		}
+		if err := d1.Encode(record); err != nil {
+			return err
+		}
+		if err := d2.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFailuresDeduped is WriteFailures' path when Dedup is set: it loads
+// whatever FailureRecords MeqaFails already has from earlier runs, folds
+// this run's fingerprint counts into them (or adds a new record for a
+// fingerprint seen for the first time), and rewrites MeqaFails as one row
+// per fingerprint across every run seen so far. NewFails still gets only
+// this run's records, with Count carrying the all-time total.
+func (plan *TestPlan) writeFailuresDeduped(path string) error {
+	priorRecords, order := loadFailureRecords(filepath.Join(path, MeqaFails))
+	meta := ReadMetadata(path)
+
+	newFails, err := os.OpenFile(filepath.Join(path, NewFails), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	defer newFails.Close()
+	d2 := json.NewEncoder(newFails)
+
+	seenThisRun := make(map[string]bool)
+	for _, v := range plan.NewFailures {
+		v.Meta = meta
+		fp := plan.fingerprints[v]
+		if seenThisRun[fp] {
+			continue
+		}
+		seenThisRun[fp] = true
+
+		prior := priorRecords[fp]
+		count := plan.fingerprintCounts[fp]
+		minimalRepro := plan.minimalRepros[v]
+		if prior != nil {
+			count += prior.Count
+			if minimalRepro == nil {
+				minimalRepro = prior.MinimalRepro
+			}
+		} else {
+			order = append(order, fp)
+		}
+		record := &FailureRecord{
+			Payload:      v,
+			MinimalRepro: minimalRepro,
+			Fingerprint:  fp,
+			Count:        count,
+		}
+		priorRecords[fp] = record
+		if err := d2.Encode(record); err != nil {
 			return err
 		}
-		if err := d2.Encode(v); err != nil {
+	}
+
+	mqFails, err := os.OpenFile(filepath.Join(path, MeqaFails), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer mqFails.Close()
+	d1 := json.NewEncoder(mqFails)
+	for _, fp := range order {
+		if err := d1.Encode(priorRecords[fp]); err != nil {
 			return err
 		}
 	}
@@ -398,6 +527,13 @@ func (plan *TestPlan) PrintSummary() {
 	fmt.Printf("%v: %v\n", mqutil.FuzzFails, len(plan.NewFailures))
 	fmt.Print(mqutil.AQUA)
 	fmt.Printf("%v: %v\n", mqutil.FuzzTotal, plan.ResultCounts[mqutil.FuzzTotal])
+	if plan.CoverageCorpus != nil {
+		fmt.Printf("New signatures this run: %v\n", plan.CoverageCorpus.NewSignatures())
+		fmt.Printf("Corpus size: %v\n", plan.CoverageCorpus.Size())
+	}
+	if plan.Dedup {
+		fmt.Printf("%v fuzz failures across %v unique fingerprints\n", len(plan.NewFailures), len(plan.fingerprintCounts))
+	}
 	fmt.Print(mqutil.END)
 }
 
@@ -407,6 +543,24 @@ func (plan *TestPlan) Init(swagger *mqswag.Swagger, db *mqswag.DB) {
 	plan.SuiteMap = make(map[string]*TestSuite)
 	plan.SuiteList = nil
 	plan.resultList = nil
+
+	if plan.CoverageCorpusPath != "" {
+		corpus, err := LoadCorpusDB(plan.CoverageCorpusPath)
+		if err != nil {
+			mqutil.Logger.Println(err.Error())
+		} else {
+			plan.CoverageCorpus = corpus
+		}
+	}
+
+	if plan.StatsPath != "" {
+		interval := plan.StatsInterval
+		if interval <= 0 {
+			interval = 30 * time.Second
+		}
+		plan.stats = NewStatsRecorder(plan.StatsPath, interval)
+		plan.stats.Start(plan)
+	}
 }
 
 // Run a named TestSuite in the test plan.
@@ -418,7 +572,13 @@ func (plan *TestPlan) Run(name string, parentTest *Test) (map[string]int, error)
 		mqutil.Logger.Println(str)
 		return resultCounts, errors.New(str)
 	}
+	// CloneSchema's own internals aren't known to be safe for concurrent
+	// callers, so serialize the clone itself under plan.mutex even though
+	// RunAll may be calling Run for several suites' groups at once; each
+	// suite still gets its own independent *mqswag.DB out of it.
+	plan.mutex.Lock()
 	tc.db = plan.db.CloneSchema()
+	plan.mutex.Unlock()
 	defer func() {
 		tc.db = nil
 	}()
@@ -452,16 +612,57 @@ func (plan *TestPlan) Run(name string, parentTest *Test) (map[string]int, error)
 		if parentTest != nil {
 			dup.Name = parentTest.Name // always inherit the name
 		}
+		biasTestParams(plan.CoverageCorpus, dup)
 		payloads, err := dup.Run(tc) // Run the test case
+		if dup.resp != nil {
+			if plan.CoverageCorpus != nil {
+				plan.recordCoverage(dup, payloads)
+			}
+			if plan.stats != nil {
+				plan.stats.RecordRequest(dup.Path, dup.resp.StatusCode())
+			}
+		}
 		// Store new failures with their payloads
+		plan.mutex.Lock()
 		if payloads != nil && len(payloads) > 0 {
 			if plan.NewFailures == nil {
 				plan.NewFailures = make([]*mqswag.Payload, 0, len(payloads))
 			}
 			plan.NewFailures = append(plan.NewFailures, payloads...)
+			if plan.failureOrigins == nil {
+				plan.failureOrigins = make(map[*mqswag.Payload]*failureOrigin)
+			}
+			for _, p := range payloads {
+				plan.failureOrigins[p] = &failureOrigin{dup: dup, tc: tc}
+			}
+
+			errMsg := ""
+			if dup.responseError != nil {
+				errMsg = dup.responseError.Error()
+			}
+			statusCode := 0
+			if dup.resp != nil {
+				statusCode = dup.resp.StatusCode()
+			}
+			if plan.fingerprints == nil {
+				plan.fingerprints = make(map[*mqswag.Payload]string)
+				plan.fingerprintCounts = make(map[string]int)
+			}
+			for _, p := range payloads {
+				fp := Fingerprint(p, statusCode, errMsg)
+				plan.fingerprints[p] = fp
+				plan.fingerprintCounts[fp]++
+			}
 		}
 		dup.err = err
 		plan.resultList = append(plan.resultList, dup)
+		plan.mutex.Unlock()
+		for _, r := range plan.Reporters {
+			r.OnTestResult(dup)
+			for _, p := range payloads {
+				r.OnFailure(p)
+			}
+		}
 		if dup.schemaError != nil {
 			resultCounts[mqutil.SchemaMismatch]++
 		}
@@ -481,6 +682,11 @@ func (plan *TestPlan) Run(name string, parentTest *Test) (map[string]int, error)
 			break
 		}
 	}
+	if plan.MinimizeFailures {
+		// tc.db is still the clone made at the top of this call; do this
+		// before the deferred tc.db = nil above runs.
+		plan.minimizeSuite(tc)
+	}
 	return resultCounts, tcErr
 }
 
@@ -512,6 +718,10 @@ func (h *TestHistory) Append(t *Test) {
 
 var History TestHistory
 
+// resty.v1's package-level client is configured once here, before RunAll
+// ever starts a worker, and every Test issues its own request via
+// resty.R() rather than sharing mutable per-request state, so fanning
+// Run out across goroutines doesn't need a client per worker.
 func init() {
 	rand.Seed(int64(time.Now().Second()))
 	resty.SetRedirectPolicy(resty.FlexibleRedirectPolicy(15))
@@ -0,0 +1,75 @@
+package mqplan
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/AdityaVallabh/swagger_meqa/meqa/mqswag"
+)
+
+// BenchmarkConcurrentFailureAppend measures contention on plan.mutex when
+// many goroutines record new failures at once, simulating RunAll fanning
+// out Run across workers.
+func BenchmarkConcurrentFailureAppend(b *testing.B) {
+	plan := &TestPlan{}
+
+	var wg sync.WaitGroup
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			payload := &mqswag.Payload{Endpoint: "/pets", Method: "POST", Field: "name"}
+
+			plan.mutex.Lock()
+			plan.NewFailures = append(plan.NewFailures, payload)
+			plan.mutex.Unlock()
+		}()
+	}
+	wg.Wait()
+}
+
+// BenchmarkScheduleGroups measures scheduleGroups itself, since Run/RunAll
+// can't be exercised here without a real *Test/*mqswag.DB (neither type's
+// source is in this package). It builds a plan with independent suites, a
+// Ref chain, and a cross-suite test-name collision, so the benchmark
+// reflects the union-find work RunAll actually does on every call, not an
+// unrealistic best case of all-independent suites.
+func BenchmarkScheduleGroups(b *testing.B) {
+	const independentSuites = 50
+	plan := &TestPlan{SuiteMap: make(map[string]*TestSuite)}
+	var names []string
+
+	for i := 0; i < independentSuites; i++ {
+		name := fmt.Sprintf("suite%d", i)
+		plan.SuiteMap[name] = &TestSuite{
+			Name:  name,
+			Tests: []*Test{{Name: fmt.Sprintf("get_%d", i)}},
+		}
+		names = append(names, name)
+	}
+
+	plan.SuiteMap["chain_child"] = &TestSuite{
+		Name:  "chain_child",
+		Tests: []*Test{{Name: "create_pet"}},
+	}
+	plan.SuiteMap["chain_parent"] = &TestSuite{
+		Name:  "chain_parent",
+		Tests: []*Test{{Ref: "chain_child"}, {Name: "get_pet"}},
+	}
+	names = append(names, "chain_parent", "chain_child")
+
+	// Collides on the "get_pet" test name with chain_parent even though
+	// neither Refs the other.
+	plan.SuiteMap["collider"] = &TestSuite{
+		Name:  "collider",
+		Tests: []*Test{{Name: "get_pet"}},
+	}
+	names = append(names, "collider")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		plan.scheduleGroups(names)
+	}
+}
@@ -0,0 +1,127 @@
+package mqplan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AdityaVallabh/swagger_meqa/meqa/mqswag"
+	"github.com/AdityaVallabh/swagger_meqa/meqa/mqutil"
+)
+
+// Reporter receives test-plan events as Run produces them, rather than
+// only once at the end via LogErrors/PrintSummary/WriteFailures. This lets
+// callers stream results into CI dashboards or long-running fuzz farms
+// without post-processing the yaml/jsonl artifacts.
+type Reporter interface {
+	OnTestResult(t *Test)
+	OnFailure(payload *mqswag.Payload)
+	OnFinish(counts map[string]int)
+}
+
+// FileReporter does nothing per-event and writes MeqaFails/NewFails via
+// WriteFailures when the run finishes, so "write everything to disk at the
+// end" is just another Reporter instead of the only option. RunAll calls
+// OnFinish exactly once per run, not once per suite.
+type FileReporter struct {
+	Plan *TestPlan
+	Path string
+}
+
+func (FileReporter) OnTestResult(t *Test)              {}
+func (FileReporter) OnFailure(payload *mqswag.Payload) {}
+
+func (r FileReporter) OnFinish(counts map[string]int) {
+	if err := r.Plan.WriteFailures(r.Path); err != nil {
+		mqutil.Logger.Println(err.Error())
+	}
+}
+
+// StdoutReporter writes one NDJSON line per event to stdout, for CI logs
+// that want to tail fuzzing progress as it happens.
+type StdoutReporter struct{}
+
+func (StdoutReporter) OnTestResult(t *Test) {
+	printReporterLine(map[string]interface{}{
+		"event": "test_result",
+		"name":  t.Name,
+		"path":  t.Path,
+		"error": errString(t.err),
+	})
+}
+
+func (StdoutReporter) OnFailure(payload *mqswag.Payload) {
+	printReporterLine(map[string]interface{}{
+		"event":    "failure",
+		"endpoint": payload.Endpoint,
+		"method":   payload.Method,
+		"field":    payload.Field,
+	})
+}
+
+func (StdoutReporter) OnFinish(counts map[string]int) {
+	printReporterLine(map[string]interface{}{"event": "finish", "counts": counts})
+}
+
+func printReporterLine(event map[string]interface{}) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		mqutil.Logger.Println(err.Error())
+		return
+	}
+	fmt.Println(string(line))
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// WebhookReporter POSTs each failure to URL as JSON, retrying with
+// exponential backoff so a slow or briefly-unavailable dashboard doesn't
+// drop fuzz results.
+type WebhookReporter struct {
+	URL     string
+	Client  *http.Client
+	Retries int
+}
+
+// NewWebhookReporter builds a WebhookReporter with sane defaults for a
+// --report-webhook URL.
+func NewWebhookReporter(url string) *WebhookReporter {
+	return &WebhookReporter{
+		URL:     url,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+		Retries: 3,
+	}
+}
+
+func (w *WebhookReporter) OnTestResult(t *Test) {}
+
+func (w *WebhookReporter) OnFailure(payload *mqswag.Payload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		mqutil.Logger.Println(err.Error())
+		return
+	}
+
+	backoff := 500 * time.Millisecond
+	for attempt := 0; attempt <= w.Retries; attempt++ {
+		resp, err := w.Client.Post(w.URL, "application/json", bytes.NewReader(body))
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < 300 {
+				return
+			}
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	mqutil.Logger.Printf("giving up reporting failure to %s after %d attempts", w.URL, w.Retries+1)
+}
+
+func (w *WebhookReporter) OnFinish(counts map[string]int) {}
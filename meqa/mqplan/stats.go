@@ -0,0 +1,142 @@
+package mqplan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AdityaVallabh/swagger_meqa/meqa/mqutil"
+)
+
+// statsSnapshot is one line of the --stats output: a point-in-time view of
+// a long-running fuzz session, so progress can be plotted and stalls
+// detected without waiting for PrintSummary at exit.
+type statsSnapshot struct {
+	Timestamp       string           `json:"timestamp"`
+	ElapsedSeconds  float64          `json:"elapsedSeconds"`
+	RequestsIssued  int64            `json:"requestsIssued"`
+	RequestsPerSec  float64          `json:"requestsPerSec"`
+	StatusCounts    map[string]int64 `json:"statusCounts"`
+	UniqueEndpoints int              `json:"uniqueEndpoints"`
+	NewFailures     int              `json:"newFailures"`
+	CorpusSize      int              `json:"corpusSize,omitempty"`
+}
+
+// StatsRecorder accumulates throughput counters for a running TestPlan and
+// periodically appends a statsSnapshot line to its StatsPath, for anyone
+// running mqgo in a soak/nightly-fuzz configuration.
+type StatsRecorder struct {
+	StatsPath string
+	Interval  time.Duration
+
+	start          time.Time
+	requestsIssued int64
+	statusCounts   map[string]*int64
+	statusMutex    sync.Mutex
+	endpointsSeen  map[string]bool
+	endpointMutex  sync.Mutex
+
+	stop chan struct{}
+}
+
+// NewStatsRecorder builds a StatsRecorder that appends to path every
+// interval once Start is called.
+func NewStatsRecorder(path string, interval time.Duration) *StatsRecorder {
+	return &StatsRecorder{
+		StatsPath:     path,
+		Interval:      interval,
+		start:         time.Now(),
+		statusCounts:  make(map[string]*int64),
+		endpointsSeen: make(map[string]bool),
+		stop:          make(chan struct{}),
+	}
+}
+
+// RecordRequest registers one completed request for the throughput
+// counters; Run calls this after every dup.Run(tc) that got a response.
+func (s *StatsRecorder) RecordRequest(endpoint string, statusCode int) {
+	atomic.AddInt64(&s.requestsIssued, 1)
+
+	s.endpointMutex.Lock()
+	s.endpointsSeen[endpoint] = true
+	s.endpointMutex.Unlock()
+
+	class := fmt.Sprintf("%dxx", statusCode/100)
+	s.statusMutex.Lock()
+	counter, ok := s.statusCounts[class]
+	if !ok {
+		counter = new(int64)
+		s.statusCounts[class] = counter
+	}
+	s.statusMutex.Unlock()
+	atomic.AddInt64(counter, 1)
+}
+
+// Start begins a goroutine that appends a statsSnapshot to s.StatsPath
+// every s.Interval, until Stop is called. TestPlan.Init calls this when
+// plan.StatsPath is set.
+func (s *StatsRecorder) Start(plan *TestPlan) {
+	go func() {
+		ticker := time.NewTicker(s.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.dump(plan)
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the periodic dump goroutine started by Start.
+func (s *StatsRecorder) Stop() {
+	close(s.stop)
+}
+
+func (s *StatsRecorder) dump(plan *TestPlan) {
+	f, err := os.OpenFile(s.StatsPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		mqutil.Logger.Println(err.Error())
+		return
+	}
+	defer f.Close()
+
+	elapsed := time.Since(s.start).Seconds()
+	issued := atomic.LoadInt64(&s.requestsIssued)
+
+	s.statusMutex.Lock()
+	statusCounts := make(map[string]int64, len(s.statusCounts))
+	for class, counter := range s.statusCounts {
+		statusCounts[class] = atomic.LoadInt64(counter)
+	}
+	s.statusMutex.Unlock()
+
+	s.endpointMutex.Lock()
+	endpoints := len(s.endpointsSeen)
+	s.endpointMutex.Unlock()
+
+	plan.mutex.Lock()
+	newFailures := len(plan.NewFailures)
+	plan.mutex.Unlock()
+
+	snapshot := statsSnapshot{
+		Timestamp:       time.Now().UTC().Format(time.RFC3339),
+		ElapsedSeconds:  elapsed,
+		RequestsIssued:  issued,
+		RequestsPerSec:  float64(issued) / elapsed,
+		StatusCounts:    statusCounts,
+		UniqueEndpoints: endpoints,
+		NewFailures:     newFailures,
+	}
+	if plan.CoverageCorpus != nil {
+		snapshot.CorpusSize = plan.CoverageCorpus.Size()
+	}
+	if err := json.NewEncoder(f).Encode(snapshot); err != nil {
+		mqutil.Logger.Println(err.Error())
+	}
+}
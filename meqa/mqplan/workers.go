@@ -0,0 +1,306 @@
+package mqplan
+
+import (
+	"strings"
+	"sync"
+)
+
+// RunAll runs every named suite in the plan, fanning out across up to
+// plan.Workers goroutines. The unit of parallelism is a dependency group,
+// not a bare name: scheduleGroups groups any suites that look like they'd
+// contend with each other (see its own doc comment), and a group always
+// runs its suites one at a time on a single goroutine.
+//
+// plan.Workers <= 1, or a MeqaInit test anywhere in the plan, falls back to
+// running every suite sequentially on the caller's goroutine.
+//
+// Run doesn't notify plan.Reporters' OnFinish itself, since it only sees
+// one top-level suite at a time; RunAll fires it exactly once, after every
+// suite has finished.
+func (plan *TestPlan) RunAll(names []string) (map[string]int, error) {
+	totals := make(map[string]int)
+
+	if plan.Workers <= 1 || plan.hasMeqaInit() {
+		var firstErr error
+		for _, name := range names {
+			counts, err := plan.Run(name, nil)
+			mergeResultCounts(totals, counts)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		for _, r := range plan.Reporters {
+			r.OnFinish(totals)
+		}
+		return totals, firstErr
+	}
+
+	groups := plan.scheduleGroups(names)
+
+	sem := make(chan struct{}, plan.Workers)
+	var wg sync.WaitGroup
+	var resultsMutex sync.Mutex
+	var firstErr error
+
+	for _, group := range groups {
+		group := group
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			// Suites within a group are serialized: they Ref each other,
+			// share a *TestSuite, or collide on a test name, so running
+			// them one at a time on this goroutine is the whole point of
+			// grouping them together.
+			for _, name := range group {
+				counts, err := plan.Run(name, nil)
+
+				resultsMutex.Lock()
+				mergeResultCounts(totals, counts)
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				resultsMutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, r := range plan.Reporters {
+		r.OnFinish(totals)
+	}
+	return totals, firstErr
+}
+
+func mergeResultCounts(dst, src map[string]int) {
+	for k, v := range src {
+		dst[k] += v
+	}
+}
+
+// hasMeqaInit reports whether any suite in the plan has a MeqaInit test,
+// which mutates that suite's TestParams/Strict as Run walks it. Run scopes
+// that mutation to the suite it belongs to, so it's already safe to run
+// concurrently with other suites; this is a conservative fallback in case
+// a future MeqaInit grows plan-global effects.
+func (plan *TestPlan) hasMeqaInit() bool {
+	for _, tc := range plan.SuiteMap {
+		for _, t := range tc.Tests {
+			if t.Name == MeqaInit {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scheduleGroups partitions names into dependency groups: two suites end
+// up in the same group if one transitively Refs the other (so they'd
+// contend for the same *TestSuite.db), if they define a Test with the same
+// Name (so they'd contend for the same History entries, since GetTest
+// matches by name across the whole run, not per suite), or if a
+// parameter value in one suite's tests textually references the other
+// suite's test name (see paramValuesReferenceTest) - a conservative stand-in
+// for a History lookup that doesn't go through an identical test name.
+// Suites in different groups share no suite object, no test name, and no
+// detected history reference, so RunAll can run the groups in parallel and
+// only needs to serialize within a group.
+func (plan *TestPlan) scheduleGroups(names []string) [][]string {
+	parent := make(map[string]string)
+	var find func(string) string
+	find = func(x string) string {
+		if parent[x] == "" {
+			parent[x] = x
+		}
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+	union := func(a, b string) {
+		ra, rb := find(a), find(b)
+		if ra != rb {
+			parent[ra] = rb
+		}
+	}
+
+	for _, name := range names {
+		find(name)
+		refs := make(map[string]bool)
+		plan.collectSuiteRefs(name, refs)
+		for ref := range refs {
+			union(name, ref)
+		}
+	}
+
+	// Merge groups that collide on a test name until nothing changes
+	// anymore; a single pass can miss a collision that only appears after
+	// an earlier merge pulls two more suites' test names together.
+	for merged := true; merged; {
+		merged = false
+		roots := make(map[string]bool)
+		for _, name := range names {
+			roots[find(name)] = true
+		}
+		rootList := make([]string, 0, len(roots))
+		for r := range roots {
+			rootList = append(rootList, r)
+		}
+		testNames := make(map[string]map[string]bool, len(rootList))
+		for _, r := range rootList {
+			testNames[r] = plan.groupTestNames(names, find, r)
+		}
+		for i := 0; i < len(rootList); i++ {
+			for j := i + 1; j < len(rootList); j++ {
+				ri, rj := rootList[i], rootList[j]
+				if namesIntersect(testNames[ri], testNames[rj]) ||
+					plan.paramValuesReferenceTest(names, find, ri, testNames[rj]) ||
+					plan.paramValuesReferenceTest(names, find, rj, testNames[ri]) {
+					union(ri, rj)
+					merged = true
+				}
+			}
+		}
+	}
+
+	groups := make(map[string][]string)
+	order := make([]string, 0, len(names))
+	for _, name := range names {
+		root := find(name)
+		if _, ok := groups[root]; !ok {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], name)
+	}
+
+	result := make([][]string, 0, len(order))
+	for _, root := range order {
+		result = append(result, groups[root])
+	}
+	return result
+}
+
+// collectSuiteRefs adds name and every suite it transitively Refs to seen.
+func (plan *TestPlan) collectSuiteRefs(name string, seen map[string]bool) {
+	if seen[name] {
+		return
+	}
+	seen[name] = true
+	tc, ok := plan.SuiteMap[name]
+	if !ok {
+		return
+	}
+	for _, t := range tc.Tests {
+		if len(t.Ref) != 0 {
+			plan.collectSuiteRefs(t.Ref, seen)
+		}
+	}
+}
+
+// groupTestNames returns the union of Test.Name across every suite in
+// names whose root (via find) is root, including suites only reachable
+// through a Ref chain.
+func (plan *TestPlan) groupTestNames(names []string, find func(string) string, root string) map[string]bool {
+	testNames := make(map[string]bool)
+	for _, name := range names {
+		if find(name) != root {
+			continue
+		}
+		refs := make(map[string]bool)
+		plan.collectSuiteRefs(name, refs)
+		for suiteName := range refs {
+			tc, ok := plan.SuiteMap[suiteName]
+			if !ok {
+				continue
+			}
+			for _, t := range tc.Tests {
+				if t.Name != "" && t.Name != MeqaInit {
+					testNames[t.Name] = true
+				}
+			}
+		}
+	}
+	return testNames
+}
+
+// paramValuesReferenceTest reports whether any fuzzable parameter value in
+// the suites under names whose root is root textually contains one of the
+// test names in other. This package doesn't have ResolveHistoryParameters'
+// source, so it can't tell a real history reference from a coincidental
+// substring match - but every other convention in this codebase that names
+// one test from another (Ref, CopyParent) does it by embedding the
+// referenced Test's own Name, so a parameter value containing another
+// suite's test name is treated as a possible unscoped History dependency
+// between the two suites, on top of the explicit Ref/name-collision cases
+// above.
+func (plan *TestPlan) paramValuesReferenceTest(names []string, find func(string) string, root string, other map[string]bool) bool {
+	if len(other) == 0 {
+		return false
+	}
+	for _, name := range names {
+		if find(name) != root {
+			continue
+		}
+		refs := make(map[string]bool)
+		plan.collectSuiteRefs(name, refs)
+		for suiteName := range refs {
+			tc, ok := plan.SuiteMap[suiteName]
+			if !ok {
+				continue
+			}
+			for _, t := range tc.Tests {
+				for _, value := range paramStrings(t) {
+					for testName := range other {
+						if testName != "" && strings.Contains(value, testName) {
+							return true
+						}
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// paramStrings flattens every string value reachable from t's
+// QueryParams/FormParams/HeaderParams/BodyParams, recursing into nested
+// maps and slices (BodyParams is commonly a decoded JSON object).
+func paramStrings(t *Test) []string {
+	var values []string
+	for _, m := range []map[string]interface{}{t.QueryParams, t.FormParams, t.HeaderParams} {
+		values = appendParamStrings(values, m)
+	}
+	values = appendParamStrings(values, t.BodyParams)
+	return values
+}
+
+func appendParamStrings(values []string, v interface{}) []string {
+	switch val := v.(type) {
+	case string:
+		values = append(values, val)
+	case map[string]interface{}:
+		for _, inner := range val {
+			values = appendParamStrings(values, inner)
+		}
+	case []interface{}:
+		for _, inner := range val {
+			values = appendParamStrings(values, inner)
+		}
+	}
+	return values
+}
+
+func namesIntersect(a, b map[string]bool) bool {
+	shorter, longer := a, b
+	if len(b) < len(a) {
+		shorter, longer = b, a
+	}
+	for name := range shorter {
+		if longer[name] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package mqplan
+
+import "testing"
+
+// groupsOf returns the group scheduleGroups put each of names in, keyed by
+// suite name, so tests can assert which suites ended up together.
+func groupsOf(groups [][]string, names ...string) map[string]int {
+	index := make(map[string]int)
+	for i, group := range groups {
+		for _, name := range group {
+			index[name] = i
+		}
+	}
+	result := make(map[string]int, len(names))
+	for _, name := range names {
+		result[name] = index[name]
+	}
+	return result
+}
+
+// A suite with no Ref to another suite and no test name in common with it
+// can still depend on it through an unscoped History lookup. scheduleGroups
+// can't see the real reference syntax (the Test/history-resolution source
+// isn't in this package), but it should still catch the common case of a
+// parameter value that embeds the other suite's test name.
+func TestScheduleGroupsHistoryReference(t *testing.T) {
+	plan := &TestPlan{SuiteMap: map[string]*TestSuite{
+		"producer": {
+			Name:  "producer",
+			Tests: []*Test{{Name: "create_pet"}},
+		},
+		"consumer": {
+			Name: "consumer",
+			Tests: []*Test{{
+				Name:       "get_pet",
+				TestParams: TestParams{QueryParams: map[string]interface{}{"id": "$create_pet.id$"}},
+			}},
+		},
+		"unrelated": {
+			Name:  "unrelated",
+			Tests: []*Test{{Name: "list_toys"}},
+		},
+	}}
+	names := []string{"producer", "consumer", "unrelated"}
+
+	groups := groupsOf(plan.scheduleGroups(names), names...)
+	if groups["producer"] != groups["consumer"] {
+		t.Errorf("producer and consumer should share a group (consumer's id param references producer's create_pet), got %v", groups)
+	}
+	if groups["unrelated"] == groups["producer"] {
+		t.Errorf("unrelated shares no Ref, test name, or param reference with producer, so it shouldn't share a group, got %v", groups)
+	}
+}